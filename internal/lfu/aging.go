@@ -0,0 +1,177 @@
+package lfu
+
+import (
+	"time"
+
+	"lfucache/internal/linkedlist"
+)
+
+// WithAging configures periodic-halving aging: once StartAging is called,
+// every period the frequency of every entry is replaced by decay(freq),
+// raised to 1 if it comes out lower, where decay defaults to freq/2 if nil.
+// This keeps a once-hot key that
+// has since gone cold from squatting on a high frequency forever and
+// polluting the cache against colder, more currently-useful keys.
+//
+// Decaying every entry is O(capacity) moves. A move into a frequency that
+// has no bucket yet scans down for the nearest lower bucket, which is
+// O(frequency) in the worst case (see insertFrequencyBucket).
+func WithAging[K comparable, V any](period time.Duration, decay func(freq int) int) Option[K, V] {
+	if decay == nil {
+		decay = func(freq int) int { return freq / 2 }
+	}
+	return func(c *сacheImpl[K, V]) {
+		c.agingPeriod = period
+		c.agingDecay = decay
+	}
+}
+
+// WithWindow configures windowed aging: the cache remembers the last n
+// frequency-incrementing hits, and once a hit falls out of that window
+// (because n more have happened since), the corresponding entry's
+// frequency is decremented - so frequency reflects recent popularity
+// within a moving window rather than a lifetime count. Recording a hit is
+// O(1). Decrementing the aged-out entry is O(1) when a bucket for its new
+// frequency already exists; otherwise insertFrequencyBucket scans down for
+// the nearest lower bucket, O(frequency) in the worst case.
+func WithWindow[K comparable, V any](n int) Option[K, V] {
+	return func(c *сacheImpl[K, V]) {
+		c.windowSize = n
+		c.windowBuf = make([]K, n)
+	}
+}
+
+// StartAging starts the periodic-halving goroutine configured by
+// WithAging. Calling it without WithAging, or while one is already
+// running, is a no-op.
+//
+// Like StartJanitor, this goroutine takes no lock, so it does not
+// synchronize with other calls made on the cache while it runs.
+// NewConcurrent offers no aging option.
+func (l *сacheImpl[K, V]) StartAging() {
+	if l.agingPeriod <= 0 || l.agingStop != nil {
+		return
+	}
+
+	l.agingStop = make(chan struct{})
+	l.agingDone = make(chan struct{})
+
+	go func() {
+		defer close(l.agingDone)
+
+		ticker := time.NewTicker(l.agingPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				l.decayAll()
+			case <-l.agingStop:
+				return
+			}
+		}
+	}()
+}
+
+// StopAging stops the goroutine started by StartAging and waits for it to
+// exit. Calling it when no aging goroutine is running is a no-op.
+func (l *сacheImpl[K, V]) StopAging() {
+	if l.agingStop == nil {
+		return
+	}
+
+	close(l.agingStop)
+	<-l.agingDone
+
+	l.agingStop = nil
+	l.agingDone = nil
+}
+
+// decayAll applies agingDecay to every entry's frequency. It walks from
+// the highest frequency down to the lowest and snapshots (node, old
+// frequency) pairs before moving anything, so a decayed entry dropped into
+// a lower, not-yet-visited bucket is never decayed twice in the same pass,
+// and the lists being walked aren't mutated mid-walk.
+func (l *сacheImpl[K, V]) decayAll() {
+	type decayed struct {
+		node    *linkedlist.Node[cacheElement[K, V]]
+		oldFreq int
+	}
+
+	var toDecay []decayed
+	for frequencyList := range l.cache.FromTail() {
+		for elementNode := range frequencyList.FromTail() {
+			if node, ok := l.items[elementNode.key]; ok {
+				toDecay = append(toDecay, decayed{node: node, oldFreq: elementNode.freq})
+			}
+		}
+	}
+
+	for _, d := range toDecay {
+		newFreq := l.agingDecay(d.oldFreq)
+		if newFreq < 1 {
+			newFreq = 1
+		}
+		if newFreq != d.oldFreq {
+			l.moveToFrequency(d.node, d.oldFreq, newFreq)
+		}
+	}
+}
+
+// recordWindowHit records a frequency-incrementing hit on key for windowed
+// aging (see WithWindow), decrementing whichever key's hit just fell out
+// of the window. A no-op if WithWindow wasn't used.
+func (l *сacheImpl[K, V]) recordWindowHit(key K) {
+	if l.windowSize <= 0 {
+		return
+	}
+
+	var agedOutKey K
+	agedOut := l.windowCount >= l.windowSize
+	if agedOut {
+		agedOutKey = l.windowBuf[l.windowPos]
+	}
+
+	l.windowBuf[l.windowPos] = key
+	l.windowPos = (l.windowPos + 1) % l.windowSize
+	if l.windowCount < l.windowSize {
+		l.windowCount++
+	}
+
+	if agedOut {
+		l.decrementFrequency(agedOutKey)
+	}
+}
+
+// decrementFrequency lowers key's frequency by one, if it's still cached
+// and not already at the floor of 1. A no-op if the key was since evicted.
+func (l *сacheImpl[K, V]) decrementFrequency(key K) {
+	node, ok := l.items[key]
+	if !ok {
+		return
+	}
+
+	oldFreq := node.Value.freq
+	if oldFreq <= 1 {
+		return
+	}
+
+	l.moveToFrequency(node, oldFreq, oldFreq-1)
+}
+
+// moveToFrequency detaches node from its oldFreq bucket and re-inserts it
+// under newFreq, collapsing the old bucket if it's left empty. Shared by
+// decayAll and decrementFrequency.
+func (l *сacheImpl[K, V]) moveToFrequency(node *linkedlist.Node[cacheElement[K, V]], oldFreq, newFreq int) {
+	oldFrequencyNode := l.frequencies[oldFreq]
+	oldFrequencyList := oldFrequencyNode.Value
+
+	oldFrequencyList.Pop(node)
+	if oldFrequencyList.Size() == 0 {
+		l.cache.Pop(oldFrequencyNode)
+		delete(l.frequencies, oldFreq)
+	}
+
+	node.Value.freq = newFreq
+	l.pushToFrequency(newFreq, node)
+}
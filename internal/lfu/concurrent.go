@@ -0,0 +1,225 @@
+package lfu
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"iter"
+	"reflect"
+	"sync"
+)
+
+// Hasher assigns a key to a shard. See WithHasher.
+type Hasher[K comparable] func(key K) uint64
+
+// ConcurrentOption configures a cache constructed via NewConcurrent.
+type ConcurrentOption[K comparable, V any] func(*concurrentCache[K, V])
+
+// WithHasher overrides the default key hasher used to pick a shard for a
+// key. The default, built on reflect and fnv, handles common key types
+// (strings, integers) reasonably; anything else should supply its own.
+func WithHasher[K comparable, V any](h Hasher[K]) ConcurrentOption[K, V] {
+	return func(c *concurrentCache[K, V]) {
+		c.hasher = h
+	}
+}
+
+// shard is one independently-locked slice of a concurrent cache.
+type shard[K comparable, V any] struct {
+	mu    sync.Mutex
+	cache *сacheImpl[K, V]
+}
+
+// concurrentCache is a Cache[K, V] that hashes keys across N independent
+// сacheImpl shards, each guarded by its own sync.Mutex, so unrelated keys
+// don't contend with each other. The plain сacheImpl is not safe for
+// concurrent use on its own - this is the wrapper that makes it so.
+type concurrentCache[K comparable, V any] struct {
+	capacity int
+	shards   []*shard[K, V]
+	hasher   Hasher[K]
+}
+
+// NewConcurrent constructs a concurrency-safe LFU cache with the given
+// total entry capacity, split as evenly as possible across shardCount
+// independent shards. Keys are assigned to shards by the configured
+// Hasher (see WithHasher); without one, a reflect/fnv-based default is
+// used that handles common key types.
+//
+// capacity must be either 0 (every shard is degenerately capacity-0, same
+// as New(0): Put always returns nil without storing anything) or at least
+// shardCount, so every shard gets a floor of one entry. A capacity between
+// 1 and shardCount-1 would otherwise silently starve the trailing shards
+// of any capacity at all, dropping every Put for a key that hashes to
+// them - so it panics instead.
+func NewConcurrent[K comparable, V any](capacity, shardCount int, opts ...ConcurrentOption[K, V]) Cache[K, V] {
+	if shardCount <= 0 {
+		panic("lfu cache shard count must be positive")
+	}
+	if capacity < 0 {
+		panic("lfu cache capacity is negative")
+	}
+	if capacity > 0 && capacity < shardCount {
+		panic("lfu cache capacity must be 0 or at least shard count, so every shard gets a nonzero share")
+	}
+
+	c := &concurrentCache[K, V]{
+		capacity: capacity,
+		shards:   make([]*shard[K, V], shardCount),
+		hasher:   defaultHasher[K](),
+	}
+
+	// split capacity evenly, handing the remainder to the first shards
+	base, remainder := capacity/shardCount, capacity%shardCount
+	for i := range c.shards {
+		shardCapacity := base
+		if i < remainder {
+			shardCapacity++
+		}
+		c.shards[i] = &shard[K, V]{cache: New[K, V](shardCapacity)}
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+func (c *concurrentCache[K, V]) shardFor(key K) *shard[K, V] {
+	return c.shards[c.hasher(key)%uint64(len(c.shards))]
+}
+
+func (c *concurrentCache[K, V]) Get(key K) (V, error) {
+	s := c.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache.Get(key)
+}
+
+func (c *concurrentCache[K, V]) Put(key K, value V) error {
+	s := c.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache.Put(key, value)
+}
+
+func (c *concurrentCache[K, V]) GetKeyFrequency(key K) (int, error) {
+	s := c.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache.GetKeyFrequency(key)
+}
+
+func (c *concurrentCache[K, V]) Size() int {
+	total := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		total += s.cache.Size()
+		s.mu.Unlock()
+	}
+	return total
+}
+
+func (c *concurrentCache[K, V]) Capacity() int {
+	return c.capacity
+}
+
+// shardEntry is a snapshotted (key, value, freq) triple pulled from a shard
+// while it was locked, used to merge shards in All without holding every
+// shard's lock for the duration of the caller's iteration.
+type shardEntry[K comparable, V any] struct {
+	key   K
+	value V
+	freq  int
+}
+
+// All snapshots each shard under its own lock, then merges the snapshots
+// with a small heap over the shard heads so the combined iterator comes out
+// in the same globally descending-frequency order All documents for a
+// single cache.
+func (c *concurrentCache[K, V]) All() iter.Seq2[K, V] {
+	streams := make([][]shardEntry[K, V], len(c.shards))
+	for i, s := range c.shards {
+		s.mu.Lock()
+		for k, v := range s.cache.All() {
+			freq, err := s.cache.GetKeyFrequency(k)
+			if err != nil {
+				continue
+			}
+			streams[i] = append(streams[i], shardEntry[K, V]{key: k, value: v, freq: freq})
+		}
+		s.mu.Unlock()
+	}
+
+	return func(yield func(K, V) bool) {
+		h := make(shardHeap[K, V], 0, len(streams))
+		for i, stream := range streams {
+			if len(stream) > 0 {
+				h = append(h, shardHeapItem[K, V]{entry: stream[0], stream: i})
+			}
+		}
+		heap.Init(&h)
+
+		for h.Len() > 0 {
+			item := heap.Pop(&h).(shardHeapItem[K, V])
+			if !yield(item.entry.key, item.entry.value) {
+				return
+			}
+			if next := item.pos + 1; next < len(streams[item.stream]) {
+				heap.Push(&h, shardHeapItem[K, V]{entry: streams[item.stream][next], stream: item.stream, pos: next})
+			}
+		}
+	}
+}
+
+// shardHeapItem is one shard's current head entry while merging All.
+type shardHeapItem[K comparable, V any] struct {
+	entry  shardEntry[K, V]
+	stream int
+	pos    int
+}
+
+// shardHeap is a container/heap.Interface ordering items by descending
+// frequency, so Pop always returns the globally most-frequent head.
+type shardHeap[K comparable, V any] []shardHeapItem[K, V]
+
+func (h shardHeap[K, V]) Len() int           { return len(h) }
+func (h shardHeap[K, V]) Less(i, j int) bool { return h[i].entry.freq > h[j].entry.freq }
+func (h shardHeap[K, V]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *shardHeap[K, V]) Push(x any)        { *h = append(*h, x.(shardHeapItem[K, V])) }
+func (h *shardHeap[K, V]) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// defaultHasher builds a Hasher for common comparable key types (strings,
+// integers, ...) using reflect to dispatch and fnv for the actual hash.
+// Anything not specifically handled falls back to hashing its %v form.
+func defaultHasher[K comparable]() Hasher[K] {
+	return func(key K) uint64 {
+		h := fnv.New64a()
+		v := reflect.ValueOf(key)
+
+		switch v.Kind() {
+		case reflect.String:
+			_, _ = h.Write([]byte(v.String()))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			var buf [8]byte
+			binary.LittleEndian.PutUint64(buf[:], uint64(v.Int()))
+			_, _ = h.Write(buf[:])
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			var buf [8]byte
+			binary.LittleEndian.PutUint64(buf[:], v.Uint())
+			_, _ = h.Write(buf[:])
+		default:
+			_, _ = fmt.Fprintf(h, "%v", key)
+		}
+
+		return h.Sum64()
+	}
+}
@@ -0,0 +1,73 @@
+package lfu
+
+// WithOnEvict registers a hook called whenever evictLFU removes an entry
+// to make room for a new one, with the evicted entry's last-known value
+// and frequency. It is not called for lazy TTL expiry - see WithOnMiss for
+// that - only for genuine LFU/size-pressure eviction.
+func WithOnEvict[K comparable, V any](f func(key K, value V, freq int)) Option[K, V] {
+	return func(c *сacheImpl[K, V]) {
+		c.onEvict = f
+	}
+}
+
+// WithOnHit registers a hook called whenever Get is served from memory.
+func WithOnHit[K comparable, V any](f func(key K)) Option[K, V] {
+	return func(c *сacheImpl[K, V]) {
+		c.onHit = f
+	}
+}
+
+// WithOnMiss registers a hook called whenever Get isn't served directly
+// from memory - whether the key is entirely absent, its entry has expired,
+// or it had to be backfilled from the store.
+func WithOnMiss[K comparable, V any](f func(key K)) Option[K, V] {
+	return func(c *сacheImpl[K, V]) {
+		c.onMiss = f
+	}
+}
+
+// WithOnInsert registers a hook called whenever a brand new entry is
+// recorded in the in-memory index, whether via Put or via Get promoting a
+// value found in the store.
+func WithOnInsert[K comparable, V any](f func(key K, value V)) Option[K, V] {
+	return func(c *сacheImpl[K, V]) {
+		c.onInsert = f
+	}
+}
+
+// Stats is a point-in-time snapshot of a cache's cumulative counters and
+// current frequency distribution, as returned by Stats.
+type Stats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Inserts   int64
+	Size      int
+
+	// FrequencyHistogram maps each frequency currently present in the
+	// cache to the number of entries at that frequency.
+	FrequencyHistogram map[int]int
+}
+
+// Stats returns the cache's cumulative hit/miss/eviction/insert counters
+// along with its current size and frequency histogram. The histogram is
+// cheap to derive: there's already exactly one list per live frequency in
+// l.frequencies, so this is just a walk of those list sizes, not the
+// entries themselves.
+//
+// O(distinct frequencies), which is at most O(capacity).
+func (l *сacheImpl[K, V]) Stats() Stats {
+	histogram := make(map[int]int, len(l.frequencies))
+	for freq, frequencyNode := range l.frequencies {
+		histogram[freq] = frequencyNode.Value.Size()
+	}
+
+	return Stats{
+		Hits:               l.hits.Load(),
+		Misses:             l.misses.Load(),
+		Evictions:          l.evictions.Load(),
+		Inserts:            l.inserts.Load(),
+		Size:               l.size,
+		FrequencyHistogram: histogram,
+	}
+}
@@ -3,11 +3,17 @@ package lfu
 import (
 	"errors"
 	"iter"
+	"sync/atomic"
+	"time"
+
 	"lfucache/internal/linkedlist"
+	"lfucache/internal/store"
 )
 
 var ErrKeyNotFound = errors.New("key not found")
 
+// DefaultCapacity is a reasonable capacity to pass to New when the caller
+// has no specific sizing requirements, e.g. New[string, int](lfu.DefaultCapacity).
 const DefaultCapacity = 5
 
 // Cache
@@ -25,8 +31,12 @@ type Cache[K comparable, V any] interface {
 	// before inserting a new item. For this problem, when there is a tie
 	// (i.e., two or more keys with the same frequency), the least recently used key would be invalidated.
 	//
+	// If the cache is configured with WithMaxSize, Put also evicts LFU entries
+	// until the value fits, and returns ErrSizeExceedCapacity without inserting
+	// if the value alone is larger than the configured size.
+	//
 	// O(1)
-	Put(key K, value V)
+	Put(key K, value V) error
 
 	// All returns the iterator in descending order of frequency.
 	// If two or more keys have the same frequency, the most recently used key will be listed first.
@@ -56,6 +66,15 @@ type cacheElement[K comparable, V any] struct {
 	key   K
 	value V
 	freq  int
+
+	// expiresAt is the Unix-time instant after which the entry is treated as
+	// absent. A zero value means the entry never expires.
+	expiresAt time.Time
+
+	// size is the value's size as last reported by the cache's ValueSizer
+	// (1 if none is configured), cached here so evictions don't need to
+	// recompute it.
+	size int
 }
 
 // CacheImpl realization of Cache interface
@@ -65,50 +84,198 @@ type сacheImpl[K comparable, V any] struct {
 	items       map[K]*linkedlist.Node[cacheElement[K, V]]
 	frequencies map[int]*linkedlist.Node[linkedlist.LinkedList[cacheElement[K, V]]]
 	cache       linkedlist.LinkedList[linkedlist.LinkedList[cacheElement[K, V]]]
+
+	// ttl is the default time-to-live applied to entries put via Put.
+	// Zero means entries never expire unless PutWithTTL is used.
+	ttl time.Duration
+
+	janitorStop chan struct{}
+	janitorDone chan struct{}
+
+	// maxSize is the configured byte/weight capacity (see WithMaxSize).
+	// Zero means the cache is only bounded by its entry capacity.
+	maxSize int64
+	// sizeBytes is the running total of every entry's size.
+	sizeBytes int64
+	// sizer reports the size of a value (see WithValueSizer). Nil means
+	// every value has a size of 1, so sizeBytes tracks the entry count.
+	sizer func(V) (int, error)
+
+	// store is an optional backing store the cache writes through/reads
+	// through to (see WithStore). Nil means the cache is purely in-memory.
+	store store.Store[K, V]
+	// writeBack suppresses store.Delete when an entry is evicted for space
+	// (see WithWriteBack).
+	writeBack bool
+
+	// agingPeriod/agingDecay configure periodic-halving aging
+	// (see WithAging); agingStop/agingDone back StartAging/StopAging.
+	agingPeriod time.Duration
+	agingDecay  func(freq int) int
+	agingStop   chan struct{}
+	agingDone   chan struct{}
+
+	// windowSize/windowBuf/windowPos/windowCount implement windowed aging
+	// (see WithWindow): a ring buffer of the last windowSize frequency-
+	// incrementing hits, used to decrement a key's frequency once its hit
+	// falls out of the window.
+	windowSize  int
+	windowBuf   []K
+	windowPos   int
+	windowCount int
+
+	// onEvict/onHit/onMiss/onInsert are optional observability hooks (see
+	// WithOnEvict and friends). Nil means no hook is called.
+	onEvict  func(key K, value V, freq int)
+	onHit    func(key K)
+	onMiss   func(key K)
+	onInsert func(key K, value V)
+
+	// hits/misses/evictions/inserts back Stats.
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+	inserts   atomic.Int64
+
+	// snapshotCodec overrides how Snapshot/Restore marshal values, for a V
+	// that doesn't encode cleanly with encoding/gob on its own (see
+	// WithSnapshotCodec).
+	snapshotCodec *Codec[V]
 }
 
-func New[K comparable, V any](capacity ...int) *сacheImpl[K, V] {
-	lfuCapacity := DefaultCapacity
-	// determination of capacity
-	if len(capacity) > 0 {
-		if capacity[0] < 0 {
-			panic("lfu cache capacity is negative")
-		}
-		lfuCapacity = capacity[0]
+// New constructs an LFU cache with the given entry capacity and options.
+//
+// By default the cache is only bounded by its entry capacity. Combine
+// WithMaxSize and WithValueSizer to additionally cap it by total value size.
+func New[K comparable, V any](capacity int, opts ...Option[K, V]) *сacheImpl[K, V] {
+	if capacity < 0 {
+		panic("lfu cache capacity is negative")
 	}
 
 	// constructing new element
-	return &сacheImpl[K, V]{
-		capacity:    lfuCapacity,
-		items:       make(map[K]*linkedlist.Node[cacheElement[K, V]], lfuCapacity),
+	c := &сacheImpl[K, V]{
+		capacity:    capacity,
+		items:       make(map[K]*linkedlist.Node[cacheElement[K, V]], capacity),
 		frequencies: make(map[int]*linkedlist.Node[linkedlist.LinkedList[cacheElement[K, V]]]),
 		cache:       linkedlist.New[linkedlist.LinkedList[cacheElement[K, V]]](),
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
 func (l *сacheImpl[K, V]) Get(key K) (V, error) {
 	var zero V
 	elementNode, ok := l.items[key]
 
-	// new element
+	// not resident in memory: fall through to the backing store, if any
 	if !ok {
+		if l.store == nil {
+			l.recordMiss(key)
+			return zero, ErrKeyNotFound
+		}
+
+		value, err := l.store.Get(key)
+		if err != nil {
+			l.recordMiss(key)
+			return zero, ErrKeyNotFound
+		}
+
+		// promote it into the in-memory index; it's already in the store,
+		// so this must not write through to the store again. If it doesn't
+		// fit in memory (e.g. WithMaxSize), the read still succeeded - it
+		// just won't be promoted.
+		_ = l.insertLocally(key, value, l.ttl)
+		l.recordMiss(key)
+		return value, nil
+	}
+
+	// lazily evict if the entry has expired since it was put
+	if isExpired(elementNode.Value.expiresAt) {
+		l.removeEntry(elementNode)
+		l.recordMiss(key)
 		return zero, ErrKeyNotFound
 	}
 
 	l.incrementFrequency(elementNode)
+	l.recordHit(key)
 	return elementNode.Value.value, nil
 }
 
-func (l *сacheImpl[K, V]) Put(key K, value V) {
+// recordHit updates hit metrics and fires OnHit for a key found resident
+// and unexpired in memory.
+func (l *сacheImpl[K, V]) recordHit(key K) {
+	l.hits.Add(1)
+	if l.onHit != nil {
+		l.onHit(key)
+	}
+}
+
+// recordMiss updates miss metrics and fires OnMiss for a key not served
+// directly from memory - whether absent entirely, expired, or backfilled
+// from the store.
+func (l *сacheImpl[K, V]) recordMiss(key K) {
+	l.misses.Add(1)
+	if l.onMiss != nil {
+		l.onMiss(key)
+	}
+}
+
+func (l *сacheImpl[K, V]) Put(key K, value V) error {
+	return l.putWithTTL(key, value, l.ttl)
+}
+
+// putWithTTL is the shared implementation behind Put and PutWithTTL.
+// A zero ttl means the entry never expires.
+func (l *сacheImpl[K, V]) putWithTTL(key K, value V, ttl time.Duration) error {
 	// unable to put
 	if l.capacity == 0 {
-		return
+		return nil
+	}
+
+	// write through to the backing store before the key/frequency is ever
+	// recorded in memory, so a crash never leaves the index pointing at a
+	// value the store doesn't have
+	if l.store != nil {
+		if err := l.store.Set(key, value); err != nil {
+			return err
+		}
+	}
+
+	return l.insertLocally(key, value, ttl)
+}
+
+// insertLocally applies value to the in-memory index only. It backs both
+// putWithTTL (after the store write-through above) and Get's promotion of a
+// value found in the store but not currently resident in memory.
+func (l *сacheImpl[K, V]) insertLocally(key K, value V, ttl time.Duration) error {
+	if l.capacity == 0 {
+		return nil
+	}
+
+	newSize, err := l.valueSize(value)
+	if err != nil {
+		return err
+	}
+	if l.maxSize > 0 && int64(newSize) > l.maxSize {
+		return ErrSizeExceedCapacity
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
 	}
 
 	// put logic
 	if elementNode, ok := l.items[key]; ok {
 		// element exists
+		l.sizeBytes += int64(newSize - elementNode.Value.size)
 		elementNode.Value.value = value
+		elementNode.Value.size = newSize
+		elementNode.Value.expiresAt = expiresAt
 		l.incrementFrequency(elementNode)
 	} else {
 		// element doesn't exist
@@ -118,14 +285,29 @@ func (l *сacheImpl[K, V]) Put(key K, value V) {
 		}
 
 		// add new one
-		l.addNewEntry(key, value)
+		// with WithMaxSize, colder entries are evicted before the new one is
+		// recorded, so it is never itself evicted to make room for itself
+		for l.maxSize > 0 && l.sizeBytes+int64(newSize) > l.maxSize && l.size > 0 {
+			l.evictLFU()
+		}
+		l.addNewEntry(key, value, expiresAt, newSize)
 	}
+
+	// evict colder entries until the configured byte size is respected;
+	// the eviction order itself is unchanged, only the stop condition is new
+	if l.maxSize > 0 {
+		for l.sizeBytes > l.maxSize && l.size > 0 {
+			l.evictLFU()
+		}
+	}
+
+	return nil
 }
 
 // adds new entry to a list
 // creates new frequency list if needed
-func (l *сacheImpl[K, V]) addNewEntry(key K, value V) {
-	newElement := cacheElement[K, V]{key: key, value: value, freq: 1}
+func (l *сacheImpl[K, V]) addNewEntry(key K, value V, expiresAt time.Time, size int) {
+	newElement := cacheElement[K, V]{key: key, value: value, freq: 1, expiresAt: expiresAt, size: size}
 	newElementNode := &linkedlist.Node[cacheElement[K, V]]{Value: newElement}
 
 	// push to init frequency
@@ -133,6 +315,12 @@ func (l *сacheImpl[K, V]) addNewEntry(key K, value V) {
 
 	l.items[key] = newElementNode
 	l.size++
+	l.sizeBytes += int64(size)
+
+	l.inserts.Add(1)
+	if l.onInsert != nil {
+		l.onInsert(key, value)
+	}
 }
 
 // IncrementFrequency increments frequency of a node
@@ -156,6 +344,12 @@ func (l *сacheImpl[K, V]) incrementFrequency(elementNode *linkedlist.Node[cache
 		l.cache.Pop(frequencyNode)
 		delete(l.frequencies, frequency)
 	}
+
+	// record the hit for windowed aging once the node has fully settled
+	// into its new bucket - a small enough window can immediately age the
+	// very entry just incremented back down, which needs the move above
+	// to already be done
+	l.recordWindowHit(elementNode.Value.key)
 }
 
 func (l *сacheImpl[K, V]) pushToFrequency(frequency int, elementNode *linkedlist.Node[cacheElement[K, V]]) {
@@ -163,22 +357,44 @@ func (l *сacheImpl[K, V]) pushToFrequency(frequency int, elementNode *linkedlis
 	if frequencyNode, ok := l.frequencies[frequency]; ok {
 		frequencyList = frequencyNode.Value
 	} else {
-		// create new list if frequency doesn't exist
-		frequencyList = linkedlist.New[cacheElement[K, V]]()
-		frequencyNode = &linkedlist.Node[linkedlist.LinkedList[cacheElement[K, V]]]{Value: frequencyList}
-
-		// init frequency
-		if frequency == 1 {
-			l.cache.PushFront(frequencyNode)
-		} else { // next frequency
-			l.cache.PushAfter(l.frequencies[frequency-1], frequencyNode)
-		}
-		l.frequencies[frequency] = frequencyNode
+		frequencyList = l.insertFrequencyBucket(frequency).Value
 	}
 
 	frequencyList.PushBack(elementNode)
 }
 
+// insertFrequencyBucket creates a new, empty frequency list for frequency
+// and inserts its node into l.cache at the position that keeps buckets in
+// ascending frequency order, then records and returns it.
+//
+// The common case - frequency is one more than an existing bucket, as
+// happens on every ordinary increment - is O(1): that neighbour is checked
+// first and found immediately. Aging can decay a frequency down by more
+// than one step, though, potentially skipping over frequencies with no
+// entries left; inserting those buckets falls back to an O(frequency) scan
+// for the nearest surviving lower frequency.
+func (l *сacheImpl[K, V]) insertFrequencyBucket(frequency int) *linkedlist.Node[linkedlist.LinkedList[cacheElement[K, V]]] {
+	frequencyList := linkedlist.New[cacheElement[K, V]]()
+	frequencyNode := &linkedlist.Node[linkedlist.LinkedList[cacheElement[K, V]]]{Value: frequencyList}
+
+	var prev *linkedlist.Node[linkedlist.LinkedList[cacheElement[K, V]]]
+	for f := frequency - 1; f > 0; f-- {
+		if node, ok := l.frequencies[f]; ok {
+			prev = node
+			break
+		}
+	}
+
+	if prev == nil {
+		l.cache.PushFront(frequencyNode)
+	} else {
+		l.cache.PushAfter(prev, frequencyNode)
+	}
+
+	l.frequencies[frequency] = frequencyNode
+	return frequencyNode
+}
+
 // delete lfu element
 func (l *сacheImpl[K, V]) evictLFU() {
 	frequencyNode := l.cache.Head()
@@ -197,6 +413,33 @@ func (l *сacheImpl[K, V]) evictLFU() {
 		panic("Frequency list is empty")
 	}
 
+	key := elementNode.Value.key
+	value := elementNode.Value.value
+	freq := elementNode.Value.freq
+	l.removeEntry(elementNode)
+
+	// the value still lives in the store unless write-back is configured,
+	// in which case the store is the only copy left and must be kept
+	if l.store != nil && !l.writeBack {
+		_ = l.store.Delete(key)
+	}
+
+	l.evictions.Add(1)
+	if l.onEvict != nil {
+		l.onEvict(key, value, freq)
+	}
+}
+
+// removeEntry detaches elementNode from its frequency list and the items
+// index, collapsing the frequency list/node if it becomes empty. Unlike
+// evictLFU, the node does not have to be the least frequently used one -
+// this is also used to lazily drop expired entries found elsewhere in the
+// cache.
+func (l *сacheImpl[K, V]) removeEntry(elementNode *linkedlist.Node[cacheElement[K, V]]) {
+	frequency := elementNode.Value.freq
+	frequencyNode := l.frequencies[frequency]
+	frequencyList := frequencyNode.Value
+
 	delete(l.items, elementNode.Value.key)
 
 	frequencyList.Pop(elementNode)
@@ -204,18 +447,32 @@ func (l *сacheImpl[K, V]) evictLFU() {
 	// delete empty frequency node
 	if frequencyList.Size() == 0 {
 		l.cache.Pop(frequencyNode)
-		delete(l.frequencies, elementNode.Value.freq)
+		delete(l.frequencies, frequency)
 	}
 
 	l.size--
+	l.sizeBytes -= int64(elementNode.Value.size)
 }
 
 func (l *сacheImpl[K, V]) All() iter.Seq2[K, V] {
 	return func(yield func(K, V) bool) {
+		// entries found expired during the walk are removed only once the
+		// walk is done, so we don't mutate the lists we're iterating over
+		var expired []*linkedlist.Node[cacheElement[K, V]]
+		defer func() {
+			for _, elementNode := range expired {
+				l.removeEntry(elementNode)
+			}
+		}()
+
 		// starts from tail because it is the biggest frequency
 		for frequencyList := range l.cache.FromTail() {
 			// starts from tail because it is the lru element at current frequency
 			for elementNode := range frequencyList.FromTail() {
+				if isExpired(elementNode.expiresAt) {
+					expired = append(expired, l.items[elementNode.key])
+					continue
+				}
 				if !yield(elementNode.key, elementNode.value) {
 					return
 				}
@@ -239,6 +496,12 @@ func (l *сacheImpl[K, V]) GetKeyFrequency(key K) (int, error) {
 		return 0, ErrKeyNotFound
 	}
 
+	// lazily evict if the entry has expired
+	if isExpired(elementNode.Value.expiresAt) {
+		l.removeEntry(elementNode)
+		return 0, ErrKeyNotFound
+	}
+
 	// if exists return frequency
 	return elementNode.Value.freq, nil
 }
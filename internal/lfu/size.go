@@ -0,0 +1,45 @@
+package lfu
+
+import "errors"
+
+// ErrSizeExceedCapacity is returned by Put when the value's own size, as
+// reported by the configured ValueSizer, is larger than the cache's
+// WithMaxSize limit - no amount of eviction could make it fit.
+var ErrSizeExceedCapacity = errors.New("value size exceeds cache capacity")
+
+// Option configures a cache constructed via New.
+type Option[K comparable, V any] func(*сacheImpl[K, V])
+
+// WithMaxSize bounds the cache by the total size of its values, in addition
+// to its entry capacity. On Put, colder entries are evicted (in the same
+// LFU/LRU-tiebreak order as capacity-based eviction) until the total fits.
+func WithMaxSize[K comparable, V any](bytes int64) Option[K, V] {
+	return func(c *сacheImpl[K, V]) {
+		c.maxSize = bytes
+	}
+}
+
+// WithValueSizer configures how the size of a value is computed for
+// WithMaxSize. Without it, every value has a size of 1, so Bytes() simply
+// tracks the entry count.
+func WithValueSizer[K comparable, V any](sizer func(V) (int, error)) Option[K, V] {
+	return func(c *сacheImpl[K, V]) {
+		c.sizer = sizer
+	}
+}
+
+// valueSize reports the size of v via the configured sizer, defaulting to 1.
+func (l *сacheImpl[K, V]) valueSize(v V) (int, error) {
+	if l.sizer == nil {
+		return 1, nil
+	}
+	return l.sizer(v)
+}
+
+// Bytes returns the total size of the values currently in the cache, as
+// reported by the configured ValueSizer (or the entry count if none is set).
+//
+// O(1)
+func (l *сacheImpl[K, V]) Bytes() int64 {
+	return l.sizeBytes
+}
@@ -0,0 +1,164 @@
+package lfu
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"lfucache/internal/linkedlist"
+)
+
+// Codec overrides how Snapshot and Restore marshal a cache's values, for a
+// V that doesn't round-trip cleanly through encoding/gob on its own (an
+// interface type whose concrete implementations aren't gob.Register'd, for
+// example). See WithSnapshotCodec.
+type Codec[V any] struct {
+	Marshal   func(V) ([]byte, error)
+	Unmarshal func([]byte) (V, error)
+}
+
+// WithSnapshotCodec configures Snapshot/Restore to marshal values through
+// codec instead of encoding them as a native gob field.
+func WithSnapshotCodec[K comparable, V any](codec Codec[V]) Option[K, V] {
+	return func(c *сacheImpl[K, V]) {
+		c.snapshotCodec = &codec
+	}
+}
+
+// snapshotEntryNative is one entry in a snapshot taken without a Codec:
+// the value round-trips as a native gob field.
+type snapshotEntryNative[K comparable, V any] struct {
+	Key       K
+	Value     V
+	Freq      int
+	ExpiresAt time.Time
+	Size      int
+}
+
+// snapshotEntryCoded is one entry in a snapshot taken with a Codec: the
+// value has already been marshaled to bytes.
+type snapshotEntryCoded[K comparable] struct {
+	Key       K
+	Raw       []byte
+	Freq      int
+	ExpiresAt time.Time
+	Size      int
+}
+
+// snapshotData is the gob wire format written by Snapshot. Entries are
+// recorded ascending by frequency and, within a frequency, from least to
+// most recently used - the exact order Restore needs to feed back into
+// pushToFrequency to reproduce the original structure.
+type snapshotData[K comparable, V any] struct {
+	Capacity      int
+	HasCodec      bool
+	NativeEntries []snapshotEntryNative[K, V]
+	CodedEntries  []snapshotEntryCoded[K]
+}
+
+// Snapshot writes the full cache state - every entry plus its frequency
+// and intra-bucket LRU order - to w using encoding/gob, so it can be
+// rebuilt later with Restore into a cache that behaves identically,
+// including producing the same All() sequence.
+func (l *сacheImpl[K, V]) Snapshot(w io.Writer) error {
+	data := snapshotData[K, V]{
+		Capacity: l.capacity,
+		HasCodec: l.snapshotCodec != nil,
+	}
+
+	// walk from head (lowest frequency) to tail, and within each bucket
+	// from head (least recently used) to tail, so Restore can rebuild by
+	// simply appending each entry in the order it's read back
+	for frequencyList := range l.cache.FromHead() {
+		for elementNode := range frequencyList.FromHead() {
+			if l.snapshotCodec != nil {
+				raw, err := l.snapshotCodec.Marshal(elementNode.value)
+				if err != nil {
+					return fmt.Errorf("lfu: marshal value for key %v: %w", elementNode.key, err)
+				}
+				data.CodedEntries = append(data.CodedEntries, snapshotEntryCoded[K]{
+					Key:       elementNode.key,
+					Raw:       raw,
+					Freq:      elementNode.freq,
+					ExpiresAt: elementNode.expiresAt,
+					Size:      elementNode.size,
+				})
+				continue
+			}
+
+			data.NativeEntries = append(data.NativeEntries, snapshotEntryNative[K, V]{
+				Key:       elementNode.key,
+				Value:     elementNode.value,
+				Freq:      elementNode.freq,
+				ExpiresAt: elementNode.expiresAt,
+				Size:      elementNode.size,
+			})
+		}
+	}
+
+	return gob.NewEncoder(w).Encode(data)
+}
+
+// Restore replaces the cache's contents, and its capacity, with the state
+// written by a prior Snapshot, rebuilding items, frequencies and cache in
+// a single pass over the recorded entries. The receiver's own capacity -
+// whatever it was constructed with - is discarded in favor of the
+// recorded one, so Restore reproduces the snapshotted cache faithfully
+// regardless of what the receiver was sized for.
+func (l *сacheImpl[K, V]) Restore(r io.Reader) error {
+	var data snapshotData[K, V]
+	if err := gob.NewDecoder(r).Decode(&data); err != nil {
+		return fmt.Errorf("lfu: decode snapshot: %w", err)
+	}
+
+	entryCount := len(data.NativeEntries) + len(data.CodedEntries)
+	if entryCount > data.Capacity {
+		return fmt.Errorf("lfu: snapshot is corrupt: %d entries exceed its own recorded capacity %d", entryCount, data.Capacity)
+	}
+
+	l.capacity = data.Capacity
+	l.items = make(map[K]*linkedlist.Node[cacheElement[K, V]], entryCount)
+	l.frequencies = make(map[int]*linkedlist.Node[linkedlist.LinkedList[cacheElement[K, V]]])
+	l.cache = linkedlist.New[linkedlist.LinkedList[cacheElement[K, V]]]()
+	l.size = 0
+	l.sizeBytes = 0
+
+	if data.HasCodec {
+		if l.snapshotCodec == nil {
+			return errors.New("lfu: snapshot was written with a codec, but none is configured (see WithSnapshotCodec)")
+		}
+		for _, e := range data.CodedEntries {
+			value, err := l.snapshotCodec.Unmarshal(e.Raw)
+			if err != nil {
+				return fmt.Errorf("lfu: unmarshal value for key %v: %w", e.Key, err)
+			}
+			l.restoreEntry(e.Key, value, e.Freq, e.ExpiresAt, e.Size)
+		}
+		return nil
+	}
+
+	for _, e := range data.NativeEntries {
+		l.restoreEntry(e.Key, e.Value, e.Freq, e.ExpiresAt, e.Size)
+	}
+	return nil
+}
+
+// restoreEntry inserts one entry read back from a snapshot directly into
+// its recorded frequency bucket, bypassing the usual freq-starts-at-1 Put
+// path entirely.
+func (l *сacheImpl[K, V]) restoreEntry(key K, value V, freq int, expiresAt time.Time, size int) {
+	node := &linkedlist.Node[cacheElement[K, V]]{Value: cacheElement[K, V]{
+		key:       key,
+		value:     value,
+		freq:      freq,
+		expiresAt: expiresAt,
+		size:      size,
+	}}
+
+	l.pushToFrequency(freq, node)
+	l.items[key] = node
+	l.size++
+	l.sizeBytes += int64(size)
+}
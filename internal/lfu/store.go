@@ -0,0 +1,21 @@
+package lfu
+
+import "lfucache/internal/store"
+
+// WithStore configures a backing store the cache writes through to on Put
+// and reads through to on a Get miss, turning it into a tiered cache: the
+// in-memory LFU index holds the hot set, while s can hold everything.
+func WithStore[K comparable, V any](s store.Store[K, V]) Option[K, V] {
+	return func(c *сacheImpl[K, V]) {
+		c.store = s
+	}
+}
+
+// WithWriteBack keeps evicted values in the store instead of deleting them
+// from it, so a later Get for a since-evicted key is served from the store
+// rather than reported as a miss.
+func WithWriteBack[K comparable, V any]() Option[K, V] {
+	return func(c *сacheImpl[K, V]) {
+		c.writeBack = true
+	}
+}
@@ -0,0 +1,93 @@
+package lfu
+
+import (
+	"time"
+
+	"lfucache/internal/linkedlist"
+)
+
+// NewWithTTL constructs a cache like New, but every entry put via Put
+// expires ttl after it was written unless overridden per-key with
+// PutWithTTL. A ttl of zero behaves like New (entries never expire).
+func NewWithTTL[K comparable, V any](capacity int, ttl time.Duration) *сacheImpl[K, V] {
+	c := New[K, V](capacity)
+	c.ttl = ttl
+	return c
+}
+
+// PutWithTTL behaves like Put, but overrides the cache's default ttl for
+// this key. A ttl of zero means the entry never expires.
+func (l *сacheImpl[K, V]) PutWithTTL(key K, value V, ttl time.Duration) error {
+	return l.putWithTTL(key, value, ttl)
+}
+
+// isExpired reports whether t is a set expiration instant that has passed.
+// A zero t means the entry never expires.
+func isExpired(t time.Time) bool {
+	return !t.IsZero() && time.Now().After(t)
+}
+
+// StartJanitor starts a background goroutine that, every interval, walks
+// the cache starting from the lowest-frequency list (cold entries are the
+// most likely to be stale) and proactively evicts expired entries, instead
+// of waiting for them to be hit by the lazy eviction in Get/GetKeyFrequency/
+// All. Calling StartJanitor while one is already running is a no-op.
+//
+// The janitor takes no lock, so it does not synchronize with other calls
+// made on the cache while it runs. NewConcurrent offers no TTL or janitor.
+func (l *сacheImpl[K, V]) StartJanitor(interval time.Duration) {
+	if l.janitorStop != nil {
+		return
+	}
+
+	l.janitorStop = make(chan struct{})
+	l.janitorDone = make(chan struct{})
+
+	go func() {
+		defer close(l.janitorDone)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				l.evictExpired()
+			case <-l.janitorStop:
+				return
+			}
+		}
+	}()
+}
+
+// StopJanitor stops the janitor goroutine started by StartJanitor and waits
+// for it to exit. Calling StopJanitor when no janitor is running is a no-op.
+func (l *сacheImpl[K, V]) StopJanitor() {
+	if l.janitorStop == nil {
+		return
+	}
+
+	close(l.janitorStop)
+	<-l.janitorDone
+
+	l.janitorStop = nil
+	l.janitorDone = nil
+}
+
+// evictExpired walks the cache from the lowest frequency list to the
+// highest, removing every entry that has expired.
+func (l *сacheImpl[K, V]) evictExpired() {
+	var expired []*linkedlist.Node[cacheElement[K, V]]
+
+	for frequencyList := range l.cache.FromHead() {
+		for elementNode := range frequencyList.FromHead() {
+			if node, ok := l.items[elementNode.key]; ok && isExpired(elementNode.expiresAt) {
+				expired = append(expired, node)
+			}
+		}
+	}
+
+	for _, node := range expired {
+		l.removeEntry(node)
+	}
+}
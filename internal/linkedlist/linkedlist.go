@@ -39,6 +39,9 @@ type LinkedList[T any] interface {
 
 	// FromTail iterates list from the tail
 	FromTail() iter.Seq[T]
+
+	// FromHead iterates list from the head
+	FromHead() iter.Seq[T]
 }
 
 // linkedList realization
@@ -130,6 +133,17 @@ func (l *linkedList[T]) FromTail() iter.Seq[T] {
 	}
 }
 
+func (l *linkedList[T]) FromHead() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		// start from head
+		for node := l.Head(); node != nil && node != l.tail; node = node.next {
+			if !yield(node.Value) {
+				return
+			}
+		}
+	}
+}
+
 // Helper method to insert a node after a specified node
 func (l *linkedList[T]) insertAfter(after *Node[T], node *Node[T]) {
 	// Prepare the node for insertion
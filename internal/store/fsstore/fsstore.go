@@ -0,0 +1,72 @@
+// Package fsstore is a filesystem-backed store.Store: every key's value is
+// gob-encoded into its own file under a base directory, so a cache can
+// survive restarts without keeping everything in memory.
+package fsstore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+
+	"lfucache/internal/store"
+)
+
+// FS is a store.Store that persists values as gob-encoded files under dir.
+type FS[K comparable, V any] struct {
+	dir string
+}
+
+// New constructs a filesystem store rooted at dir, creating it if needed.
+func New[K comparable, V any](dir string) (*FS[K, V], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("fsstore: create %s: %w", dir, err)
+	}
+	return &FS[K, V]{dir: dir}, nil
+}
+
+// path maps a key to a stable file name. Keys aren't necessarily valid
+// path components (or even printable), so the file name is a hash of the
+// key's %v form rather than the key itself.
+func (f *FS[K, V]) path(key K) string {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%v", key)
+	return filepath.Join(f.dir, fmt.Sprintf("%016x", h.Sum64()))
+}
+
+func (f *FS[K, V]) Get(key K) (V, error) {
+	var zero V
+
+	data, err := os.ReadFile(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return zero, store.ErrNotFound
+	}
+	if err != nil {
+		return zero, err
+	}
+
+	var value V
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+		return zero, fmt.Errorf("fsstore: decode %v: %w", key, err)
+	}
+	return value, nil
+}
+
+func (f *FS[K, V]) Set(key K, value V) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return fmt.Errorf("fsstore: encode %v: %w", key, err)
+	}
+	return os.WriteFile(f.path(key), buf.Bytes(), 0o644)
+}
+
+func (f *FS[K, V]) Delete(key K) error {
+	err := os.Remove(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,66 @@
+// Package store defines the pluggable backing store a lfu.Cache can read
+// through and write through to, so cached values can outlive the in-memory
+// LFU index (or live somewhere other than memory entirely).
+package store
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNotFound is returned by Get when the key has no value in the store.
+var ErrNotFound = errors.New("key not found in store")
+
+// Store is a backing store for a cache: the cache's in-memory index tracks
+// metadata (frequency, expiry) while the value itself can live here.
+type Store[K comparable, V any] interface {
+	// Get returns the value of the key, or ErrNotFound if it has none.
+	Get(key K) (V, error)
+
+	// Set writes (or overwrites) the value of the key.
+	Set(key K, value V) error
+
+	// Delete removes the key's value, if any.
+	Delete(key K) error
+}
+
+// Memory is the default Store: values live in a plain map guarded by a
+// mutex, so it's only really useful for tests or as a starting point - a
+// real deployment would plug in something like fsstore.FS.
+type Memory[K comparable, V any] struct {
+	mu   sync.Mutex
+	data map[K]V
+}
+
+// NewMemory constructs an empty in-memory Store.
+func NewMemory[K comparable, V any]() *Memory[K, V] {
+	return &Memory[K, V]{data: make(map[K]V)}
+}
+
+func (m *Memory[K, V]) Get(key K) (V, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value, ok := m.data[key]
+	if !ok {
+		var zero V
+		return zero, ErrNotFound
+	}
+	return value, nil
+}
+
+func (m *Memory[K, V]) Set(key K, value V) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data[key] = value
+	return nil
+}
+
+func (m *Memory[K, V]) Delete(key K) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.data, key)
+	return nil
+}